@@ -0,0 +1,267 @@
+package dkg
+
+import (
+	"context"
+	"math/big"
+)
+
+// Complaint is broadcast by AccuserId when DealerId's stage-1 pair
+// (Share1, Share2) fails local verification against the dealer's published
+// CombinedPublicVals. Every peer, not just the dealer, must be able to
+// recheck the accusation, so the disputed shares travel with it.
+type Complaint struct {
+	AccuserId int      `json:"accuserId"`
+	DealerId  int      `json:"dealerId"`
+	Share1    *big.Int `json:"share1"`
+	Share2    *big.Int `json:"share2"`
+}
+
+// Justification is DealerId's reply to a Complaint from AccuserId: the pair
+// DealerId claims it actually sent. Every peer can independently recheck it
+// against DealerId's CombinedPublicVals without trusting either party.
+type Justification struct {
+	DealerId  int      `json:"dealerId"`
+	AccuserId int      `json:"accuserId"`
+	Share1    *big.Int `json:"share1"`
+	Share2    *big.Int `json:"share2"`
+}
+
+// complaintKey identifies a (dealer, accuser) pair, the granularity at which
+// AppendComplaint and AppendJustification dedup: one accuser gets to lodge
+// at most one counted complaint against a given dealer, no matter how many
+// times it resends or how many other dealers it also complains about.
+type complaintKey struct {
+	dealerId  int
+	accuserId int
+}
+
+// validPeerId reports whether id could possibly name a peer in this round,
+// i.e. is a valid index into Shares1/Shares2 once offset by 1. Every
+// Complaint/Justification arrives from a remote peer and must be checked
+// against it before DealerId/AccuserId are ever used as a slice index.
+func (d *Dkg) validPeerId(id int) bool {
+	return id >= 1 && id <= d.N
+}
+
+// AppendComplaint and AppendJustification gate on d.Stage == ComplaintStage
+// directly instead of going through d.store.Accept like the other Append*
+// methods do. messageStore's quota is maxPerPeer total messages per sender
+// per (stage, round); Complaint/Justification need per-dealer accounting
+// instead, since one honest accuser filing against several misbehaving
+// dealers in the same ComplaintStage is legitimate and must not be
+// squeezed down to a single global slot. complaintSeen/justificationSeen
+// already provide that per-(dealer, accuser) accounting, so only the
+// stage check is added here rather than routing through the store.
+
+// AppendComplaint records complaint, ignoring it unless d.Stage is
+// ComplaintStage (a complaint sent before stage 1 closes is stale and must
+// not be counted), AccuserId or DealerId is out of range (a malicious peer
+// could otherwise name an AccuserId that later indexes Shares1/Shares2 out of
+// bounds), or this AccuserId has already complained about this DealerId. A
+// Complaint carries no round, so one replayed from an earlier round that
+// arrives while d.Stage is ComplaintStage is not told apart from a current
+// one. Without the dedup, a
+// single malicious accuser could resend distinct-looking Complaint records
+// to push complaintsByDealer past d.T and force ComputeQualifiedSet to
+// disqualify an honest dealer unilaterally.
+func (d *Dkg) AppendComplaint(complaint *Complaint) int {
+	d.complaintMutex.Lock()
+	defer d.complaintMutex.Unlock()
+	if d.Stage != ComplaintStage {
+		return len(d.Complaints)
+	}
+	if !d.validPeerId(complaint.AccuserId) || !d.validPeerId(complaint.DealerId) {
+		return len(d.Complaints)
+	}
+	key := complaintKey{dealerId: complaint.DealerId, accuserId: complaint.AccuserId}
+	if d.complaintSeen[key] {
+		return len(d.Complaints)
+	}
+	d.complaintSeen[key] = true
+	d.Complaints = append(d.Complaints, complaint)
+	return len(d.Complaints)
+}
+
+// AppendJustification records justification, ignoring it unless d.Stage is
+// ComplaintStage, DealerId or AccuserId is out of range, or DealerId has
+// already justified to this AccuserId, for the same reasons AppendComplaint
+// gates, validates and dedups: a stale-stage reply, or a bogus id reaching a
+// slice index, or a resent or duplicated Justification, must not be
+// accepted. Like a Complaint, a Justification carries no round.
+func (d *Dkg) AppendJustification(justification *Justification) int {
+	d.justificationMutex.Lock()
+	defer d.justificationMutex.Unlock()
+	if d.Stage != ComplaintStage {
+		return len(d.Justifications)
+	}
+	if !d.validPeerId(justification.AccuserId) || !d.validPeerId(justification.DealerId) {
+		return len(d.Justifications)
+	}
+	key := complaintKey{dealerId: justification.DealerId, accuserId: justification.AccuserId}
+	if d.justificationSeen[key] {
+		return len(d.Justifications)
+	}
+	d.justificationSeen[key] = true
+	d.Justifications = append(d.Justifications, justification)
+	return len(d.Justifications)
+}
+
+func (d *Dkg) recordDealerPublicVals(dealerId int, combinedPublicVals []*big.Int) {
+	d.dealerValsMutex.Lock()
+	defer d.dealerValsMutex.Unlock()
+	d.dealerPublicVals[dealerId] = combinedPublicVals
+}
+
+func (d *Dkg) dealerPublicValsFor(dealerId int) ([]*big.Int, bool) {
+	d.dealerValsMutex.Lock()
+	defer d.dealerValsMutex.Unlock()
+	vals, ok := d.dealerPublicVals[dealerId]
+	return vals, ok
+}
+
+// ProcessStage1Payload records the dealer's commitments and locally verifies
+// the pair it sent. If verification fails, it returns the Complaint this
+// peer must broadcast to every server; callers should send it via
+// SendComplaint. A nil return means the dealer's pair checked out.
+func (d *Dkg) ProcessStage1Payload(payload *ShareStage1Payload) *Complaint {
+	d.recordDealerPublicVals(payload.Id, payload.CombinedPublicVals)
+	if d.IsQualifiedPeer(payload) {
+		return nil
+	}
+	return &Complaint{
+		AccuserId: d.Id,
+		DealerId:  payload.Id,
+		Share1:    payload.Share1,
+		Share2:    payload.Share2,
+	}
+}
+
+// ProcessComplaint records an incoming complaint so this peer can later
+// compute QUAL, and if the complaint targets this peer as dealer, builds the
+// Justification to broadcast in reply. A nil return means no reply is owed,
+// including when AccuserId is out of range: AppendComplaint already dropped
+// such a complaint, and indexing Shares1/Shares2 with it would panic.
+func (d *Dkg) ProcessComplaint(complaint *Complaint) *Justification {
+	d.AppendComplaint(complaint)
+	if complaint.DealerId != d.Id {
+		return nil
+	}
+	if !d.validPeerId(complaint.AccuserId) {
+		return nil
+	}
+	idx := complaint.AccuserId - 1
+	justification := &Justification{
+		DealerId:  d.Id,
+		AccuserId: complaint.AccuserId,
+		Share1:    d.Shares1[idx],
+	}
+	if d.Mode != ModeFeldman {
+		justification.Share2 = d.Shares2[idx]
+	}
+	return justification
+}
+
+func (d *Dkg) SendComplaint(ctx context.Context, topic string, complaint *Complaint) error {
+	return d.Transport.Broadcast(ctx, topic, complaint)
+}
+
+func (d *Dkg) SendJustification(ctx context.Context, topic string, justification *Justification) error {
+	return d.Transport.Broadcast(ctx, topic, justification)
+}
+
+// verifyJustification rechecks DealerId's claimed pair for AccuserId against
+// the CombinedPublicVals that dealer published in stage 1. ModeFeldman has
+// only Share1 and a plain g^{a_k} commitment, so the h^{b_k} term Pedersen
+// needs is dropped rather than evaluated against a nil Share2/H.
+func (d *Dkg) verifyJustification(dealerId int, justification *Justification) bool {
+	combinedPublicVals, ok := d.dealerPublicValsFor(dealerId)
+	if !ok || len(combinedPublicVals) != d.T+1 {
+		return false
+	}
+
+	product := d.computePublicValsProductForId(combinedPublicVals, justification.AccuserId)
+
+	if d.Mode == ModeFeldman {
+		gsij := new(big.Int).Exp(d.G, justification.Share1, d.P)
+		return gsij.Cmp(product) == 0
+	}
+
+	gsij := new(big.Int).Exp(d.G, justification.Share1, d.P)
+	hsij := new(big.Int).Exp(d.H, justification.Share2, d.P)
+	gMulh := new(big.Int).Mod(new(big.Int).Mul(gsij, hsij), d.P)
+	return gMulh.Cmp(product) == 0
+}
+
+// computePublicValsProductForId is computePublicValsProduct generalized to an
+// arbitrary peer id, since justifications must be rechecked on behalf of the
+// accuser rather than the verifying peer itself.
+func (d *Dkg) computePublicValsProductForId(combinedPublicVals []*big.Int, id int) *big.Int {
+	product := big.NewInt(1)
+	j := big.NewInt(int64(id))
+	for k, c := range combinedPublicVals {
+		exp := new(big.Int).Exp(j, big.NewInt(int64(k)), nil)
+		term := new(big.Int).Exp(c, exp, d.P)
+		product.Mul(product, term)
+		product.Mod(product, d.P)
+	}
+	return product
+}
+
+// ComputeQualifiedSet applies the canonical disqualification rule so every
+// honest peer converges on the same QUAL regardless of which complaints it
+// happened to see first: a dealer is disqualified if any complaint against
+// it went unanswered, its justification fails to verify, or it accumulated
+// more than T complaints (which alone means too many shares of its
+// polynomial were exposed to be safe, justified or not). AppendComplaint's
+// dedup by (DealerId, AccuserId) is what makes that count meaningful: it
+// counts distinct accusers, not raw messages, so one accuser resending or
+// duplicating a Complaint can't inflate it past T on its own.
+func (d *Dkg) ComputeQualifiedSet() []int {
+	complaintsByDealer := make(map[int][]*Complaint)
+	for _, c := range d.Complaints {
+		complaintsByDealer[c.DealerId] = append(complaintsByDealer[c.DealerId], c)
+	}
+
+	justByDealer := make(map[int]map[int]*Justification)
+	for _, j := range d.Justifications {
+		byAccuser, ok := justByDealer[j.DealerId]
+		if !ok {
+			byAccuser = make(map[int]*Justification)
+			justByDealer[j.DealerId] = byAccuser
+		}
+		byAccuser[j.AccuserId] = j
+	}
+
+	disqualified := make(map[int]bool)
+	for dealerId, complaints := range complaintsByDealer {
+		if len(complaints) > d.T {
+			disqualified[dealerId] = true
+			continue
+		}
+		for _, c := range complaints {
+			justification, ok := justByDealer[dealerId][c.AccuserId]
+			if !ok || !d.verifyJustification(dealerId, justification) {
+				disqualified[dealerId] = true
+				break
+			}
+		}
+	}
+
+	qual := make([]int, 0, d.N)
+	for id := 1; id <= d.N; id++ {
+		if !disqualified[id] {
+			qual = append(qual, id)
+		}
+	}
+	d.QUAL = qual
+	return qual
+}
+
+func (d *Dkg) inQual(id int) bool {
+	for _, v := range d.QUAL {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package dkg
 
 import (
+	"context"
 	"crypto/sha256"
 	"log"
 	"math/big"
@@ -14,6 +15,7 @@ import (
 const (
 	InitialStage = iota
 	SendShareStage1
+	ComplaintStage
 	SendShareStage2
 	EncrytionStage
 	DecryptionStage
@@ -84,10 +86,108 @@ type Dkg struct {
 	DecryptionShares     []*DecryptionShare
 	Ciphertext		   *Ciphertext
 
+	complaintMutex *sync.Mutex
+	Complaints     []*Complaint
+	complaintSeen  map[complaintKey]bool
+
+	justificationMutex *sync.Mutex
+	Justifications     []*Justification
+	justificationSeen  map[complaintKey]bool
+
+	dealerValsMutex  *sync.Mutex
+	dealerPublicVals map[int][]*big.Int
+
+	// QUAL is the canonical set of dealer ids every honest peer is expected
+	// to converge on once ComplaintStage closes.
+	QUAL []int
+
+	// Mode selects which VSS scheme generated this Dkg's shares. The zero
+	// value, ModePedersen, preserves existing behavior for Dkg values built
+	// with NewDkg.
+	Mode Mode
+
+	// RefreshEpoch counts successful Reshare calls. PublicKey never changes
+	// across a refresh; RefreshEpoch is what distinguishes one proactive
+	// resharing round from the next.
+	RefreshEpoch int
+
+	// SignatureScheme backs VerifySignature. The zero value (nil) uses
+	// discreteLogSignatureScheme.
+	SignatureScheme SignatureScheme
+
+	// Transport carries every Send* call to its peers. It must be set with
+	// SetTransport before any Send* method is used; the zero value is nil so
+	// misconfiguration fails fast instead of silently no-opping.
+	Transport Transport
+
+	// Round and Stage track where this Dkg is in the protocol; they gate
+	// the message store so Append* rejects duplicate, out-of-stage, or
+	// flooded submissions. AdvanceStage/AdvanceRound keep them and the
+	// store in sync.
+	Round int
+	Stage int
+	store *messageStore
+
+	// paras1 and paras2 are this peer's own polynomial coefficients,
+	// (paras1[0], paras2[0]) being the secret(s) this round deals. They are
+	// only needed to stay consistent with shares already sent if this peer
+	// crashes and restarts before SendShareStage2 commits the round, so
+	// AdvanceStage wipes them once that stage passes.
+	paras1 []*big.Int
+	paras2 []*big.Int
+
 	PublicKey          *big.Int
 	PrivateKey         *big.Int
 }
 
+// AdvanceStage opens the message store for a new stage within the current
+// round. Call it as the protocol's state machine moves forward (e.g. from
+// SendShareStage1 to ComplaintStage); messages for the previous stage are
+// rejected as out-of-stage afterward. Once the round has moved past
+// SendShareStage2, this peer's own polynomial coefficients are no longer
+// needed to stay consistent with shares already sent, so they are wiped.
+func (d *Dkg) AdvanceStage(stage int) {
+	d.Stage = stage
+	d.store.SetStage(d.Round, stage)
+	if stage > SendShareStage2 {
+		d.paras1 = nil
+		d.paras2 = nil
+	}
+}
+
+// AdvanceRound resets the message store and re-opens it at InitialStage for
+// a new round, discarding all per-peer submission counts from the previous
+// round. This is the garbage-collection hook a resharing DKG calls between
+// rounds.
+func (d *Dkg) AdvanceRound(round int) {
+	d.Round = round
+	d.Stage = InitialStage
+	d.store.Reset(round)
+}
+
+// MessageCount returns how many distinct peers have had a message accepted
+// for stage in the current round, per messageStore.Count.
+func (d *Dkg) MessageCount(stage int) int {
+	return d.store.Count(stage)
+}
+
+// SetTransport installs the Transport used by every Send* method. Call it
+// once after construction, e.g. d.SetTransport(NewHTTPTransport(...)).
+func (d *Dkg) SetTransport(t Transport) {
+	d.Transport = t
+}
+
+// Mode selects between the two-polynomial Pedersen VSS (unconditionally
+// hiding, used by NewDkg) and the single-polynomial Feldman VSS (only
+// computationally hiding, used by NewFeldmanDkg) that this Dkg was built
+// with.
+type Mode int
+
+const (
+	ModePedersen Mode = iota
+	ModeFeldman
+)
+
 func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
@@ -106,10 +206,21 @@ func NewDkg(g *big.Int,g_ *big.Int, h *big.Int, p *big.Int, q *big.Int, t int, n
 		shareMutex:           &sync.Mutex{},
 		publicValMutex:       &sync.Mutex{},
 		decryptionShareMutex: &sync.Mutex{},
+		complaintMutex:       &sync.Mutex{},
+		complaintSeen:        make(map[complaintKey]bool),
+		justificationMutex:   &sync.Mutex{},
+		justificationSeen:    make(map[complaintKey]bool),
+		dealerValsMutex:      &sync.Mutex{},
+		dealerPublicVals:     make(map[int][]*big.Int),
+		Stage:                SendShareStage1,
+		store:                newMessageStore(1),
 	}
+	d.store.SetStage(d.Round, d.Stage)
 
 	paras1 := generateRandomParas(t+1)
 	paras2 := generateRandomParas(t+1)
+	d.paras1 = paras1
+	d.paras2 = paras2
 
 	d.Shares1 = computeShares(func(z *big.Int) *big.Int {
 		return polynomial(paras1, z, q)
@@ -133,24 +244,44 @@ func NewDkg(g *big.Int,g_ *big.Int, h *big.Int, p *big.Int, q *big.Int, t int, n
 		PublicVal:d.PublicVals1[0],
 	}
 
+	d.dealerPublicVals[id] = d.CombinedPublicVals
+
 	return d
 }
 
-func (d *Dkg) AppendDecryptionShare(decryptionShare *DecryptionShare) int {
+// AppendDecryptionShare records decryptionShare for round, rejecting it via
+// the message store if it is a duplicate, out-of-stage, or a flood from
+// decryptionShare.Id.
+func (d *Dkg) AppendDecryptionShare(decryptionShare *DecryptionShare, round int) int {
+	if !d.store.Accept(decryptionShare.Id, DecryptionStage, round) {
+		return len(d.DecryptionShares)
+	}
 	d.decryptionShareMutex.Lock()
 	defer d.decryptionShareMutex.Unlock()
 	d.DecryptionShares = append(d.DecryptionShares, decryptionShare)
 	return len(d.DecryptionShares)
 }
 
-func (d *Dkg) AppendQualifiedPeerShare(share *PeerShare) int {
+// AppendQualifiedPeerShare records share for round, rejecting it via the
+// message store if it is a duplicate, out-of-stage, or a flood from
+// share.Id.
+func (d *Dkg) AppendQualifiedPeerShare(share *PeerShare, round int) int {
+	if !d.store.Accept(share.Id, SendShareStage1, round) {
+		return len(d.QualifiedPeerShares)
+	}
 	d.shareMutex.Lock()
 	defer d.shareMutex.Unlock()
 	d.QualifiedPeerShares = append(d.QualifiedPeerShares, share)
 	return len(d.QualifiedPeerShares)
 }
 
-func (d *Dkg) AppendQualifiedPeerPublicVal(publicVal *PeerPublicVal) int {
+// AppendQualifiedPeerPublicVal records publicVal for round, rejecting it via
+// the message store if it is a duplicate, out-of-stage, or a flood from
+// publicVal.Id.
+func (d *Dkg) AppendQualifiedPeerPublicVal(publicVal *PeerPublicVal, round int) int {
+	if !d.store.Accept(publicVal.Id, SendShareStage2, round) {
+		return len(d.QualifiedPeerPublicVals)
+	}
 	d.publicValMutex.Lock()
 	defer d.publicValMutex.Unlock()
 	d.QualifiedPeerPublicVals = append(d.QualifiedPeerPublicVals, publicVal)
@@ -194,66 +325,109 @@ func (d *Dkg) IsQualifiedPeerForStage2(payload *ShareStage2Payload) bool {
 	}
 }
 
-func (d *Dkg) SendStage1(url string) {
-	for i, v := range d.Servers {
-		if i+1 == d.Id {
+// SendStage1 unicasts each peer its own stage-1 payload over d.Transport,
+// fanning out concurrently and returning an aggregated error so the caller
+// can tell whether enough peers received their share despite individual
+// failures. In ModeFeldman there is no second polynomial, so Share2 is left
+// nil rather than indexing the empty Shares2 slice.
+func (d *Dkg) SendStage1(ctx context.Context, topic string) error {
+	errs := make(chan error, len(d.Servers))
+	var wg sync.WaitGroup
+	for i := range d.Servers {
+		peerId := i + 1
+		if peerId == d.Id {
 			continue
 		}
-		go send(&ShareStage1Payload{
-			Id:               d.Id,
-			Share1:             d.Shares1[i],
-			Share2:             d.Shares2[i],
-			CombinedPublicVals: d.CombinedPublicVals,
-		}, v+url)
+		wg.Add(1)
+		go func(peerId int) {
+			defer wg.Done()
+			payload := &ShareStage1Payload{
+				Id:                 d.Id,
+				Share1:             d.Shares1[peerId-1],
+				CombinedPublicVals: d.CombinedPublicVals,
+			}
+			if d.Mode != ModeFeldman {
+				payload.Share2 = d.Shares2[peerId-1]
+			}
+			errs <- d.Transport.Unicast(ctx, peerId, topic, payload)
+		}(peerId)
 	}
+	wg.Wait()
+	close(errs)
+	return aggregateErrors(errs)
 }
 
-func (d *Dkg) SendStage2(url string) {
-	for i, v := range d.Servers {
-		if i+1 == d.Id {
-			continue
-		}
-		go send(&ShareStage2Payload{
-			Id: d.Id,
-			Share: d.Shares1[i],
-			PublicVals: d.PublicVals1,
-		}, v+url)
+// SendStage2 unicasts each peer its stage-2 share and this dealer's public
+// vals over d.Transport. ModeFeldman has no stage-2: IsQualifiedPeer already
+// verifies a Feldman dealer's share against its single commitment set in
+// one step, so SendStage2 is a no-op in that mode.
+func (d *Dkg) SendStage2(ctx context.Context, topic string) error {
+	if d.Mode == ModeFeldman {
+		return nil
 	}
-}
 
-func (d *Dkg) SendCiphertext(ciphertext *Ciphertext, url string) {
-	for i, v := range d.Servers {
-		if i+1 == d.Id {
+	errs := make(chan error, len(d.Servers))
+	var wg sync.WaitGroup
+	for i := range d.Servers {
+		peerId := i + 1
+		if peerId == d.Id {
 			continue
 		}
-		go send(ciphertext, v+url)
+		wg.Add(1)
+		go func(peerId int) {
+			defer wg.Done()
+			payload := &ShareStage2Payload{
+				Id:         d.Id,
+				Share:      d.Shares1[peerId-1],
+				PublicVals: d.PublicVals1,
+			}
+			errs <- d.Transport.Unicast(ctx, peerId, topic, payload)
+		}(peerId)
 	}
+	wg.Wait()
+	close(errs)
+	return aggregateErrors(errs)
 }
 
-func (d *Dkg) SendDecrptionShare(decryptionShare *DecryptionShare, url string) {
-	for i, v := range d.Servers {
-		if i+1 == d.Id {
-			continue
-		}
-		go send(decryptionShare, v+url)
-	}
+func (d *Dkg) SendCiphertext(ctx context.Context, topic string, ciphertext *Ciphertext) error {
+	return d.Transport.Broadcast(ctx, topic, ciphertext)
+}
+
+func (d *Dkg) SendDecrptionShare(ctx context.Context, topic string, decryptionShare *DecryptionShare) error {
+	return d.Transport.Broadcast(ctx, topic, decryptionShare)
 }
 
+// SetPublicKey derives PublicKey from QUAL, the canonical set of dealers
+// that survived ComplaintStage. A dealer's public val is only folded in if
+// the dealer is in d.QUAL, so a disqualified dealer's commitments never
+// reach the public key even if a share for it was received before its
+// complaint was resolved.
 func (d *Dkg) SetPublicKey() {
 	d.PublicKey = big.NewInt(1)
 	for _, v := range d.QualifiedPeerPublicVals {
+		if !d.inQual(v.Id) {
+			continue
+		}
 		d.PublicKey = new(big.Int).Mod(new(big.Int).Mul(d.PublicKey, v.PublicVal),d.P)
 	}
 }
 
+// SetPrivateKey sums this peer's shares from dealers in QUAL only, mirroring
+// SetPublicKey so every honest peer's key material is derived from the same
+// disqualification decision.
 func (d *Dkg) SetPrivateKey() {
 	d.PrivateKey = big.NewInt(0)
 	for _, v := range d.QualifiedPeerShares {
+		if !d.inQual(v.Id) {
+			continue
+		}
 		d.PrivateKey.Add(d.PrivateKey, v.Share)
 	}
 	d.PrivateKey.Mod(d.PrivateKey, d.Q)
 }
 
+// Encrypt operates on PublicKey alone, reconstructed identically by SetPublicKey
+// regardless of Mode, so it needs no Mode dispatch of its own.
 func (d *Dkg) Encrypt(m *big.Int) *Ciphertext {
 
 
@@ -279,6 +453,9 @@ func (d *Dkg) Encrypt(m *big.Int) *Ciphertext {
 	}
 }
 
+// Decrypt operates on PrivateKey alone; Pedersen and Feldman shares end up
+// indistinguishable once SetPrivateKey has summed them, so no Mode dispatch
+// is needed here either.
 func (d *Dkg) Decrypt(ciphertext *Ciphertext) *DecryptionShare {
 	u := ciphertext.U
 	g := d.G
@@ -301,6 +478,9 @@ func (d *Dkg) Decrypt(ciphertext *Ciphertext) *DecryptionShare {
 	}
 }
 
+// CombineShares Lagrange-interpolates DecryptionShares in the exponent; the
+// shares it combines are already Mode-agnostic values produced by Decrypt,
+// so this step has nothing left to dispatch on.
 func (d *Dkg) CombineShares() *big.Int {
 
 	shares:= d.DecryptionShares[:d.T+1]
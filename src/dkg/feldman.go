@@ -0,0 +1,89 @@
+package dkg
+
+import (
+	"math/big"
+	"sync"
+)
+
+// NewFeldmanDkg builds a Dkg that runs Feldman VSS instead of the two
+// polynomial Pedersen scheme NewDkg uses. It trades unconditional hiding for
+// half the on-wire payload and exponentiations: a single polynomial, a
+// single share s_ij per peer, and plain commitments C_k = g^{a_k} mod p
+// instead of combined g^{a_k}·h^{b_k} commitments. This is sufficient for
+// the common TBLS case where only computational hiding is required. g_ is
+// unrelated to VSS mode — it is the second generator Encrypt/Decrypt need
+// for their Chaum-Pedersen NIZK regardless of how shares were dealt — so it
+// is still required here, unlike h.
+func NewFeldmanDkg(g *big.Int, g_ *big.Int, p *big.Int, q *big.Int, t int, n int, id int, servers []string) *Dkg {
+	d := &Dkg{
+		Id:                   id,
+		G:                    g,
+		G_:                   g_,
+		P:                    p,
+		Q:                    q,
+		T:                    t,
+		N:                    n,
+		Servers:              servers,
+		Mode:                 ModeFeldman,
+		shareMutex:           &sync.Mutex{},
+		publicValMutex:       &sync.Mutex{},
+		decryptionShareMutex: &sync.Mutex{},
+		complaintMutex:       &sync.Mutex{},
+		complaintSeen:        make(map[complaintKey]bool),
+		justificationMutex:   &sync.Mutex{},
+		justificationSeen:    make(map[complaintKey]bool),
+		dealerValsMutex:      &sync.Mutex{},
+		dealerPublicVals:     make(map[int][]*big.Int),
+		Stage:                SendShareStage1,
+		store:                newMessageStore(1),
+	}
+	d.store.SetStage(d.Round, d.Stage)
+
+	paras := generateRandomParas(t + 1)
+	d.paras1 = paras
+
+	d.Shares1 = computeShares(func(z *big.Int) *big.Int {
+		return polynomial(paras, z, q)
+	}, n)
+
+	d.PublicVals1 = computePublicVals(paras, g, t, p)
+	// Feldman has only one set of commitments; CombinedPublicVals is kept in
+	// sync so ShareStage1Payload and its verification stay shape-compatible
+	// with the Pedersen path.
+	d.CombinedPublicVals = d.PublicVals1
+
+	d.QualifiedPeerShares = make([]*PeerShare, 1, n)
+	d.QualifiedPeerShares[0] = &PeerShare{
+		Id:    id,
+		Share: d.Shares1[id-1],
+	}
+	d.QualifiedPeerPublicVals = make([]*PeerPublicVal, 1, n)
+	d.QualifiedPeerPublicVals[0] = &PeerPublicVal{
+		Id:        id,
+		PublicVal: d.PublicVals1[0],
+	}
+
+	d.dealerPublicVals[id] = d.CombinedPublicVals
+
+	return d
+}
+
+// IsQualifiedPeer verifies a dealer's stage-1 pair, dispatching on Mode. In
+// ModeFeldman the commitments already bind the real polynomial, so a single
+// check g^{s_ij} == Π C_k^{j^k} replaces the Pedersen stage-1/stage-2 pair.
+func (d *Dkg) IsQualifiedPeer(payload *ShareStage1Payload) bool {
+	if d.Mode == ModeFeldman {
+		return d.isQualifiedFeldmanPeer(payload)
+	}
+	return d.IsQualifiedPeerForStage1(payload)
+}
+
+func (d *Dkg) isQualifiedFeldmanPeer(payload *ShareStage1Payload) bool {
+	commitments := payload.CombinedPublicVals
+	if len(commitments) != d.T+1 {
+		return false
+	}
+
+	gsij := new(big.Int).Exp(d.G, payload.Share1, d.P)
+	return gsij.Cmp(d.computePublicValsProduct(commitments)) == 0
+}
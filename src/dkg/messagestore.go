@@ -0,0 +1,92 @@
+package dkg
+
+import "sync"
+
+// messageKey identifies a single sender's submissions for one protocol
+// stage within one DKG round.
+type messageKey struct {
+	senderId int
+	stage    int
+	round    int
+}
+
+// messageStore gates incoming protocol messages before they reach an
+// Append* slice. Without it, a single misbehaving peer could resubmit the
+// same stage payload repeatedly, or submit a stage-2 payload while the
+// store is still open for stage 1, inflating a slice until len() >= t+1
+// triggers premature progression. messageStore instead tracks how many
+// messages each sender has submitted for the store's currently-open
+// (round, stage) and rejects anything out-of-stage, out-of-round, or over
+// maxPerPeer.
+type messageStore struct {
+	mutex      sync.Mutex
+	maxPerPeer int
+	round      int
+	stage      int
+	counts     map[messageKey]int
+}
+
+func newMessageStore(maxPerPeer int) *messageStore {
+	return &messageStore{
+		maxPerPeer: maxPerPeer,
+		counts:     make(map[messageKey]int),
+	}
+}
+
+// SetStage opens the store for (round, stage). Messages for any other round
+// or stage are rejected as out-of-stage until the next SetStage call.
+func (s *messageStore) SetStage(round int, stage int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.round = round
+	s.stage = stage
+}
+
+// Accept reports whether a message from senderId targeting (stage, round)
+// may be appended, and if so records it against senderId's quota. It
+// returns false for duplicates and floods (senderId already at
+// maxPerPeer for this stage/round) as well as for any stage/round other
+// than the one the store is currently open for.
+func (s *messageStore) Accept(senderId int, stage int, round int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if stage != s.stage || round != s.round {
+		return false
+	}
+
+	key := messageKey{senderId: senderId, stage: stage, round: round}
+	if s.counts[key] >= s.maxPerPeer {
+		return false
+	}
+	s.counts[key]++
+	return true
+}
+
+// Count returns the number of distinct senders accepted so far for the
+// given stage in the store's current round, so a state-machine driver can
+// decide when to advance (e.g. exactly t+1 distinct valid shares) without
+// trusting a slice's len().
+func (s *messageStore) Count(stage int) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	count := 0
+	for key := range s.counts {
+		if key.stage == stage && key.round == s.round {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset clears all recorded submissions and opens the store for a new round
+// at InitialStage. It is the garbage-collection hook for resharing, where a
+// fresh DKG round reuses the same Dkg value.
+func (s *messageStore) Reset(round int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.round = round
+	s.stage = InitialStage
+	s.counts = make(map[messageKey]int)
+}
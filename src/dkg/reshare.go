@@ -0,0 +1,165 @@
+package dkg
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Reshare performs a fresh Pedersen DKG round in which this peer deals its
+// *current* secret share PrivateKey, instead of a random secret, to
+// (possibly new) committee newServers with threshold newT. It reuses the
+// existing stage-1/stage-2 machinery unchanged: it repopulates Shares1,
+// Shares2, PublicVals1 and CombinedPublicVals from a degree-newT polynomial
+// pinned at PrivateKey, so SendStage1/SendStage2 distribute them exactly as
+// in an initial DKG, and the new committee verifies and complains/justifies
+// exactly as before.
+//
+// Reshare does not by itself produce this peer's new share: every qualified
+// old dealer runs Reshare and deals concurrently, and once this peer has
+// collected a verified contribution from each one (via the usual
+// Append*/ComplaintStage flow), it must call SetPrivateKeyFromReshare with
+// the old committee's QUAL to combine them correctly.
+func (d *Dkg) Reshare(newServers []string, newT int) error {
+	if d.PrivateKey == nil {
+		return fmt.Errorf("dkg: cannot reshare before PrivateKey is set")
+	}
+	if newT < 0 || newT >= len(newServers) {
+		return fmt.Errorf("dkg: invalid threshold %d for %d new servers", newT, len(newServers))
+	}
+
+	newN := len(newServers)
+
+	paras1 := generateRefreshParas(d.PrivateKey, newT, d.Q)
+	paras2 := generateRandomParas(newT + 1)
+	d.paras1 = paras1
+	d.paras2 = paras2
+
+	d.Shares1 = computeShares(func(z *big.Int) *big.Int {
+		return polynomial(paras1, z, d.Q)
+	}, newN)
+	d.Shares2 = computeShares(func(z *big.Int) *big.Int {
+		return polynomial(paras2, z, d.Q)
+	}, newN)
+
+	d.PublicVals1 = computePublicVals(paras1, d.G, newT, d.P)
+	d.CombinedPublicVals = d.combinePublicVals(d.PublicVals1, computePublicVals(paras2, d.H, newT, d.P))
+
+	d.Servers = newServers
+	d.N = newN
+	d.T = newT
+
+	d.QualifiedPeerShares = nil
+	d.QualifiedPeerPublicVals = nil
+	d.Complaints = nil
+	d.Justifications = nil
+	d.complaintSeen = make(map[complaintKey]bool)
+	d.justificationSeen = make(map[complaintKey]bool)
+	d.QUAL = nil
+	d.dealerPublicVals = make(map[int][]*big.Int)
+
+	d.RefreshEpoch++
+	d.AdvanceRound(d.RefreshEpoch)
+	d.AdvanceStage(SendShareStage1)
+
+	if d.Id <= newN {
+		d.QualifiedPeerShares = append(d.QualifiedPeerShares, &PeerShare{
+			Id:    d.Id,
+			Share: d.Shares1[d.Id-1],
+		})
+		d.QualifiedPeerPublicVals = append(d.QualifiedPeerPublicVals, &PeerPublicVal{
+			Id:        d.Id,
+			PublicVal: d.PublicVals1[0],
+		})
+		d.dealerPublicVals[d.Id] = d.CombinedPublicVals
+	}
+
+	return nil
+}
+
+// SetPrivateKeyFromReshare combines this peer's collected contributions (one
+// PeerShare per old dealer) into its new PrivateKey, call it after this
+// round's own ComputeQualifiedSet has run. Unlike SetPrivateKey's plain sum,
+// a dealer's contribution here is itself a point on a degree-newT polynomial
+// pinned at that dealer's old share x_i, so it must be weighted by that
+// dealer's Lagrange coefficient over oldQual before summing: Σ λ_i(0)·x_i
+// reconstructs the same master secret PublicKey already commits to, so
+// PublicKey is left untouched. A contribution is only folded in if its
+// dealer is in both oldQual (it held a valid old share) and this round's
+// d.QUAL (its reshare dealing itself passed ComplaintStage) — a dealer
+// disqualified during the reshare must not still influence the new key just
+// because it was legitimate before the reshare started. On success it wipes
+// the dealing material this peer generated in Reshare, since it is no longer
+// needed and increasingly stale key material is exactly what resharing is
+// meant to retire.
+func (d *Dkg) SetPrivateKeyFromReshare(oldQual []int) error {
+	if len(oldQual) == 0 {
+		return fmt.Errorf("dkg: cannot derive reshared private key from empty oldQual")
+	}
+
+	privateKey := big.NewInt(0)
+	for _, contribution := range d.QualifiedPeerShares {
+		if !containsID(oldQual, contribution.Id) || !d.inQual(contribution.Id) {
+			continue
+		}
+		lambda := lagrangeCoefficientAtZero(oldQual, contribution.Id, d.Q)
+		weighted := new(big.Int).Mul(lambda, contribution.Share)
+		privateKey.Add(privateKey, weighted)
+		privateKey.Mod(privateKey, d.Q)
+	}
+	d.PrivateKey = privateKey
+
+	d.Shares1 = nil
+	d.Shares2 = nil
+	d.PublicVals1 = nil
+	d.paras1 = nil
+	d.paras2 = nil
+	d.QualifiedPeerShares = nil
+	d.QualifiedPeerPublicVals = nil
+	d.Complaints = nil
+	d.Justifications = nil
+	d.complaintSeen = make(map[complaintKey]bool)
+	d.justificationSeen = make(map[complaintKey]bool)
+	d.dealerPublicVals = make(map[int][]*big.Int)
+
+	return nil
+}
+
+// generateRefreshParas builds a degree-`degree` polynomial whose constant
+// term is the fixed secret (an existing share being reshared) rather than a
+// fresh random one, with every other coefficient random as usual.
+func generateRefreshParas(secret *big.Int, degree int, q *big.Int) []*big.Int {
+	paras := make([]*big.Int, degree+1)
+	paras[0] = new(big.Int).Mod(secret, q)
+	for k := 1; k <= degree; k++ {
+		paras[k] = new(big.Int).Mod(getRandomBigInt(), q)
+	}
+	return paras
+}
+
+// lagrangeCoefficientAtZero computes λ_id(0) = Π_{m ∈ ids, m != id} (0 - m) /
+// (id - m) mod q, the standard Lagrange basis coefficient for reconstructing
+// a polynomial's value at 0 from its values at ids.
+func lagrangeCoefficientAtZero(ids []int, id int, q *big.Int) *big.Int {
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	for _, m := range ids {
+		if m == id {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(-m)))
+		num.Mod(num, q)
+		den.Mul(den, big.NewInt(int64(id-m)))
+		den.Mod(den, q)
+	}
+	denInv := new(big.Int).ModInverse(den, q)
+	return new(big.Int).Mod(new(big.Int).Mul(num, denInv), q)
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package dkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transport abstracts how Dkg moves protocol messages to its peers. The
+// original implementation hardcoded an HTTP POST per peer with no retry,
+// timeout, or auth; HTTPTransport below keeps the POST per peer as the
+// default and adds a client timeout and per-peer retry/backoff, while
+// GRPCTransport and PubSubTransport let callers swap in a persistent gRPC
+// stream or a libp2p pubsub topic without touching DKG logic.
+// PubSubTransport only broadcasts: its Unicast always returns an error, so
+// SendStage1 and SendStage2 fail over it. Peers are always addressed by Id,
+// never by slice index, so a
+// transport is free to do authenticated routing.
+type Transport interface {
+	Broadcast(ctx context.Context, topic string, msg interface{}) error
+	Unicast(ctx context.Context, peerId int, topic string, msg interface{}) error
+}
+
+// HTTPTransport is the default Transport: one POST per peer, fanned out
+// concurrently, with per-peer retry/backoff and an aggregated error so the
+// caller can tell whether quorum was reached despite individual failures.
+type HTTPTransport struct {
+	Client     *http.Client
+	SelfId     int
+	PeerURLs   map[int]string // peer id -> base URL
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+func NewHTTPTransport(selfId int, peerURLs map[int]string) *HTTPTransport {
+	return &HTTPTransport{
+		Client:     &http.Client{Timeout: 5 * time.Second},
+		SelfId:     selfId,
+		PeerURLs:   peerURLs,
+		MaxRetries: 3,
+		Backoff:    200 * time.Millisecond,
+	}
+}
+
+func (t *HTTPTransport) Broadcast(ctx context.Context, topic string, msg interface{}) error {
+	errs := make(chan error, len(t.PeerURLs))
+	var wg sync.WaitGroup
+	for peerId := range t.PeerURLs {
+		if peerId == t.SelfId {
+			continue
+		}
+		wg.Add(1)
+		go func(peerId int) {
+			defer wg.Done()
+			errs <- t.Unicast(ctx, peerId, topic, msg)
+		}(peerId)
+	}
+	wg.Wait()
+	close(errs)
+	return aggregateErrors(errs)
+}
+
+func (t *HTTPTransport) Unicast(ctx context.Context, peerId int, topic string, msg interface{}) error {
+	url, ok := t.PeerURLs[peerId]
+	if !ok {
+		return fmt.Errorf("transport: no url for peer %d", peerId)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("transport: marshal %s for peer %d: %w", topic, peerId, err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= t.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(t.Backoff * time.Duration(attempt)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+topic, bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := t.Client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("transport: peer %d returned status %d", peerId, resp.StatusCode)
+	}
+	return fmt.Errorf("transport: unicast %s to peer %d failed after %d attempts: %w", topic, peerId, t.MaxRetries+1, lastErr)
+}
+
+// multiError aggregates the per-peer failures from a fan-out so a Broadcast
+// or Send* call can report exactly who failed instead of only the first
+// error.
+type multiError struct {
+	errs []error
+}
+
+func (m *multiError) Error() string {
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d send(s) failed: %s", len(m.errs), strings.Join(msgs, "; "))
+}
+
+func aggregateErrors(errs <-chan error) error {
+	var failed []error
+	for err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &multiError{errs: failed}
+}
@@ -0,0 +1,67 @@
+package dkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DkgStreamClient is the subset of a generated gRPC client GRPCTransport
+// needs: a single call that ships an encoded protocol message for a topic
+// down a peer's stream. Callers wire this up from their own .pb.go client,
+// keeping this package free of a direct google.golang.org/grpc dependency.
+type DkgStreamClient interface {
+	Send(ctx context.Context, topic string, payload []byte) error
+}
+
+// GRPCTransport implements Transport over a long-lived gRPC stream per peer,
+// for deployments where peer addresses are stable and a persistent
+// connection amortizes setup cost better than a POST per message.
+type GRPCTransport struct {
+	SelfId  int
+	Streams map[int]DkgStreamClient // peer id -> open stream
+}
+
+func NewGRPCTransport(selfId int, streams map[int]DkgStreamClient) *GRPCTransport {
+	return &GRPCTransport{SelfId: selfId, Streams: streams}
+}
+
+func (t *GRPCTransport) Broadcast(ctx context.Context, topic string, msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("transport: marshal %s: %w", topic, err)
+	}
+
+	errs := make(chan error, len(t.Streams))
+	var wg sync.WaitGroup
+	for peerId := range t.Streams {
+		if peerId == t.SelfId {
+			continue
+		}
+		wg.Add(1)
+		go func(peerId int) {
+			defer wg.Done()
+			errs <- t.sendEncoded(ctx, peerId, topic, body)
+		}(peerId)
+	}
+	wg.Wait()
+	close(errs)
+	return aggregateErrors(errs)
+}
+
+func (t *GRPCTransport) Unicast(ctx context.Context, peerId int, topic string, msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("transport: marshal %s for peer %d: %w", topic, peerId, err)
+	}
+	return t.sendEncoded(ctx, peerId, topic, body)
+}
+
+func (t *GRPCTransport) sendEncoded(ctx context.Context, peerId int, topic string, body []byte) error {
+	stream, ok := t.Streams[peerId]
+	if !ok {
+		return fmt.Errorf("transport: no grpc stream for peer %d", peerId)
+	}
+	return stream.Send(ctx, topic, body)
+}
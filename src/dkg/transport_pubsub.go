@@ -0,0 +1,57 @@
+package dkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PubSubTopic is the subset of a libp2p pubsub topic handle PubSubTransport
+// needs. Callers wire this up from their own *pubsub.Topic, keeping this
+// package free of a direct libp2p dependency.
+type PubSubTopic interface {
+	Publish(ctx context.Context, data []byte) error
+}
+
+// PubSubTransport implements Transport over a single libp2p pubsub topic,
+// for deployments that already run a libp2p swarm and want gossip-based
+// dissemination instead of direct per-peer connections.
+type PubSubTransport struct {
+	SelfId int
+	Topic  PubSubTopic
+}
+
+func NewPubSubTransport(selfId int, topic PubSubTopic) *PubSubTransport {
+	return &PubSubTransport{SelfId: selfId, Topic: topic}
+}
+
+func (t *PubSubTransport) Broadcast(ctx context.Context, topic string, msg interface{}) error {
+	return t.publish(ctx, topic, msg)
+}
+
+// Unicast has no point-to-point primitive in gossip pubsub: every publish
+// reaches the whole topic. Silently degrading to Broadcast would leak
+// Stage1/Stage2 secret shares, which Unicast is specifically relied on to
+// keep point-to-point, to every subscriber of topic. So it refuses instead;
+// callers that need real unicast should use HTTPTransport or GRPCTransport.
+func (t *PubSubTransport) Unicast(ctx context.Context, peerId int, topic string, msg interface{}) error {
+	return fmt.Errorf("transport: pubsub has no point-to-point unicast, refusing to broadcast secret payload for peer %d on %s", peerId, topic)
+}
+
+func (t *PubSubTransport) publish(ctx context.Context, topic string, msg interface{}) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("transport: marshal %s: %w", topic, err)
+	}
+
+	envelope, err := json.Marshal(struct {
+		SenderId int             `json:"senderId"`
+		Topic    string          `json:"topic"`
+		Payload  json.RawMessage `json:"payload"`
+	}{SenderId: t.SelfId, Topic: topic, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("transport: marshal envelope for %s: %w", topic, err)
+	}
+
+	return t.Topic.Publish(ctx, envelope)
+}